@@ -0,0 +1,204 @@
+package k8s_client
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const certificatesGroupVersionV1 = "certificates.k8s.io/v1"
+
+// CSR is a version-neutral view of a CertificateSigningRequest, covering the fields the
+// controller needs regardless of whether the cluster serves certificates/v1 or the deprecated
+// certificates/v1beta1.
+type CSR struct {
+	Name       string
+	Username   string
+	Groups     []string
+	Request    []byte
+	SignerName string
+	Conditions []CSRCondition
+
+	// raw holds a deep copy of the API object this CSR was converted from. Approve mutates it
+	// directly and sends it back as-is, so ResourceVersion, UID, Usages, ExpirationSeconds,
+	// Extra, and any existing Status.Conditions survive the round trip instead of being
+	// dropped by rebuilding the wire object from only the fields CSR exposes.
+	raw interface{}
+}
+
+// CSRCondition mirrors the parts of a CertificateSigningRequestCondition callers care about.
+type CSRCondition struct {
+	Type    string
+	Reason  string
+	Message string
+}
+
+// csrBackend hides the concrete certificates.k8s.io API version behind a version-neutral
+// interface, so ListCsrs/ApproveCsr can stay oblivious to which one the cluster serves.
+type csrBackend interface {
+	List() ([]CSR, error)
+	Approve(csr *CSR) error
+	// informer returns a SharedIndexInformer over CertificateSigningRequests for this backend's
+	// API version, reporting each add/update of a CSR that is not yet Approved or Denied through
+	// onChange as a version-neutral CSR.
+	informer(factory informers.SharedInformerFactory, onChange func(*CSR)) cache.SharedIndexInformer
+}
+
+// newCSRBackend discovers the certificates.k8s.io versions served by the API server and returns
+// the backend for the newest one it understands, preferring v1 and falling back to v1beta1 for
+// older clusters. client is the interface rather than the concrete *kubernetes.Clientset so a
+// fake clientset can stand in for it in tests.
+func newCSRBackend(client kubernetes.Interface, logger *logrus.Logger) (csrBackend, error) {
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(certificatesGroupVersionV1)
+	if err == nil && resources != nil {
+		logger.Info("Using certificates.k8s.io/v1 for CSR handling")
+		return &csrBackendV1{client: client.CertificatesV1().CertificateSigningRequests(), log: logger}, nil
+	}
+
+	logger.Infof("certificates.k8s.io/v1 not served, falling back to v1beta1: %v", err)
+	return &csrBackendV1beta1{client: client.CertificatesV1beta1().CertificateSigningRequests(), log: logger}, nil
+}
+
+type csrBackendV1 struct {
+	client interface {
+		List(ctx context.Context, opts metav1.ListOptions) (*certificatesv1.CertificateSigningRequestList, error)
+		UpdateApproval(ctx context.Context, name string, csr *certificatesv1.CertificateSigningRequest, opts metav1.UpdateOptions) (*certificatesv1.CertificateSigningRequest, error)
+	}
+	log *logrus.Logger
+}
+
+func (b *csrBackendV1) informer(factory informers.SharedInformerFactory, onChange func(*CSR)) cache.SharedIndexInformer {
+	inf := factory.Certificates().V1().CertificateSigningRequests().Informer()
+	registerCSRHandlers(inf, onChange, func(obj interface{}) (*CSR, bool) {
+		csr, ok := obj.(*certificatesv1.CertificateSigningRequest)
+		if !ok {
+			return nil, false
+		}
+		converted := fromV1(csr)
+		return &converted, true
+	})
+	return inf
+}
+
+func (b *csrBackendV1) List() ([]CSR, error) {
+	list, err := b.client.List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	csrs := make([]CSR, 0, len(list.Items))
+	for i := range list.Items {
+		csrs = append(csrs, fromV1(&list.Items[i]))
+	}
+	return csrs, nil
+}
+
+func (b *csrBackendV1) Approve(csr *CSR) error {
+	v1csr, ok := csr.raw.(*certificatesv1.CertificateSigningRequest)
+	if !ok {
+		return errors.Errorf("CSR %s has no certificates/v1 backing object to approve", csr.Name)
+	}
+
+	v1csr.Status.Conditions = append(v1csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+		Type:           certificatesv1.CertificateApproved,
+		Status:         "True",
+		Reason:         "NodeCSRApprove",
+		Message:        "This CSR was approved by the assisted-installer-controller",
+		LastUpdateTime: metav1.Now(),
+	})
+	_, err := b.client.UpdateApproval(context.TODO(), v1csr.Name, v1csr, metav1.UpdateOptions{})
+	return err
+}
+
+type csrBackendV1beta1 struct {
+	client interface {
+		List(ctx context.Context, opts metav1.ListOptions) (*certificatesv1beta1.CertificateSigningRequestList, error)
+		UpdateApproval(ctx context.Context, csr *certificatesv1beta1.CertificateSigningRequest, opts metav1.UpdateOptions) (*certificatesv1beta1.CertificateSigningRequest, error)
+	}
+	log *logrus.Logger
+}
+
+func (b *csrBackendV1beta1) informer(factory informers.SharedInformerFactory, onChange func(*CSR)) cache.SharedIndexInformer {
+	inf := factory.Certificates().V1beta1().CertificateSigningRequests().Informer()
+	registerCSRHandlers(inf, onChange, func(obj interface{}) (*CSR, bool) {
+		csr, ok := obj.(*certificatesv1beta1.CertificateSigningRequest)
+		if !ok {
+			return nil, false
+		}
+		converted := fromV1beta1(csr)
+		return &converted, true
+	})
+	return inf
+}
+
+func (b *csrBackendV1beta1) List() ([]CSR, error) {
+	list, err := b.client.List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	csrs := make([]CSR, 0, len(list.Items))
+	for i := range list.Items {
+		csrs = append(csrs, fromV1beta1(&list.Items[i]))
+	}
+	return csrs, nil
+}
+
+func (b *csrBackendV1beta1) Approve(csr *CSR) error {
+	v1beta1csr, ok := csr.raw.(*certificatesv1beta1.CertificateSigningRequest)
+	if !ok {
+		return errors.Errorf("CSR %s has no certificates/v1beta1 backing object to approve", csr.Name)
+	}
+
+	v1beta1csr.Status.Conditions = append(v1beta1csr.Status.Conditions, certificatesv1beta1.CertificateSigningRequestCondition{
+		Type:           certificatesv1beta1.CertificateApproved,
+		Reason:         "NodeCSRApprove",
+		Message:        "This CSR was approved by the assisted-installer-controller",
+		LastUpdateTime: metav1.Now(),
+	})
+	_, err := b.client.UpdateApproval(context.TODO(), v1beta1csr, metav1.UpdateOptions{})
+	return err
+}
+
+func fromV1(csr *certificatesv1.CertificateSigningRequest) CSR {
+	conditions := make([]CSRCondition, 0, len(csr.Status.Conditions))
+	for _, c := range csr.Status.Conditions {
+		conditions = append(conditions, CSRCondition{Type: string(c.Type), Reason: c.Reason, Message: c.Message})
+	}
+	return CSR{
+		Name:       csr.Name,
+		Username:   csr.Spec.Username,
+		Groups:     csr.Spec.Groups,
+		Request:    csr.Spec.Request,
+		SignerName: csr.Spec.SignerName,
+		Conditions: conditions,
+		raw:        csr.DeepCopy(),
+	}
+}
+
+func fromV1beta1(csr *certificatesv1beta1.CertificateSigningRequest) CSR {
+	conditions := make([]CSRCondition, 0, len(csr.Status.Conditions))
+	for _, c := range csr.Status.Conditions {
+		conditions = append(conditions, CSRCondition{Type: string(c.Type), Reason: c.Reason, Message: c.Message})
+	}
+	signerName := ""
+	if csr.Spec.SignerName != nil {
+		signerName = *csr.Spec.SignerName
+	}
+	return CSR{
+		Name:       csr.Name,
+		Username:   csr.Spec.Username,
+		Groups:     csr.Spec.Groups,
+		Request:    csr.Spec.Request,
+		SignerName: signerName,
+		Conditions: conditions,
+		raw:        csr.DeepCopy(),
+	}
+}
@@ -0,0 +1,149 @@
+package k8s_client
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestK8sClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "k8s_client Suite")
+}
+
+var _ = Describe("newCSRBackend", func() {
+	It("prefers certificates/v1 when the API server serves it", func() {
+		clientset := fake.NewSimpleClientset()
+		clientset.Resources = []*metav1.APIResourceList{
+			{
+				GroupVersion: certificatesGroupVersionV1,
+				APIResources: []metav1.APIResource{{Name: "certificatesigningrequests", Kind: "CertificateSigningRequest"}},
+			},
+		}
+
+		backend, err := newCSRBackend(clientset, logrus.New())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(backend).To(BeAssignableToTypeOf(&csrBackendV1{}))
+	})
+
+	It("falls back to certificates/v1beta1 when v1 is not served", func() {
+		clientset := fake.NewSimpleClientset()
+		clientset.Resources = []*metav1.APIResourceList{
+			{
+				GroupVersion: "certificates.k8s.io/v1beta1",
+				APIResources: []metav1.APIResource{{Name: "certificatesigningrequests", Kind: "CertificateSigningRequest"}},
+			},
+		}
+
+		backend, err := newCSRBackend(clientset, logrus.New())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(backend).To(BeAssignableToTypeOf(&csrBackendV1beta1{}))
+	})
+})
+
+var _ = Describe("csrBackendV1", func() {
+	var (
+		clientset *fake.Clientset
+		backend   *csrBackendV1
+	)
+
+	BeforeEach(func() {
+		clientset = fake.NewSimpleClientset(&certificatesv1.CertificateSigningRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "csr-1", ResourceVersion: "42", UID: "uid-1"},
+			Spec: certificatesv1.CertificateSigningRequestSpec{
+				Username:   "system:node:worker-1",
+				Groups:     []string{"system:nodes"},
+				Request:    []byte("request-bytes"),
+				SignerName: "kubernetes.io/kube-apiserver-client-kubelet",
+				Usages:     []certificatesv1.KeyUsage{certificatesv1.UsageClientAuth},
+			},
+		})
+		backend = &csrBackendV1{client: clientset.CertificatesV1().CertificateSigningRequests(), log: logrus.New()}
+	})
+
+	It("lists CSRs and converts them to the version-neutral CSR type", func() {
+		csrs, err := backend.List()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(csrs).To(HaveLen(1))
+		Expect(csrs[0].Name).To(Equal("csr-1"))
+		Expect(csrs[0].Username).To(Equal("system:node:worker-1"))
+		Expect(csrs[0].SignerName).To(Equal("kubernetes.io/kube-apiserver-client-kubelet"))
+	})
+
+	It("approves a CSR without dropping fields the wire object already carried", func() {
+		csrs, err := backend.List()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(csrs).To(HaveLen(1))
+
+		Expect(backend.Approve(&csrs[0])).To(Succeed())
+
+		updated, err := clientset.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), "csr-1", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updated.ResourceVersion).To(Equal("42"))
+		Expect(updated.UID).To(BeEquivalentTo("uid-1"))
+		Expect(updated.Spec.Usages).To(ConsistOf(certificatesv1.UsageClientAuth))
+		Expect(updated.Status.Conditions).To(HaveLen(1))
+		Expect(updated.Status.Conditions[0].Type).To(Equal(certificatesv1.CertificateApproved))
+	})
+
+	It("rejects approving a CSR that was never converted from a v1 object", func() {
+		err := backend.Approve(&CSR{Name: "hand-built"})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("csrBackendV1beta1", func() {
+	var (
+		clientset *fake.Clientset
+		backend   *csrBackendV1beta1
+	)
+
+	BeforeEach(func() {
+		signerName := "kubernetes.io/kube-apiserver-client-kubelet"
+		clientset = fake.NewSimpleClientset(&certificatesv1beta1.CertificateSigningRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "csr-2", ResourceVersion: "7", UID: "uid-2"},
+			Spec: certificatesv1beta1.CertificateSigningRequestSpec{
+				Username:   "system:node:worker-2",
+				Groups:     []string{"system:nodes"},
+				Request:    []byte("request-bytes"),
+				SignerName: &signerName,
+			},
+		})
+		backend = &csrBackendV1beta1{client: clientset.CertificatesV1beta1().CertificateSigningRequests(), log: logrus.New()}
+	})
+
+	It("lists CSRs and converts them to the version-neutral CSR type", func() {
+		csrs, err := backend.List()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(csrs).To(HaveLen(1))
+		Expect(csrs[0].Name).To(Equal("csr-2"))
+		Expect(csrs[0].SignerName).To(Equal("kubernetes.io/kube-apiserver-client-kubelet"))
+	})
+
+	It("approves a CSR without dropping fields the wire object already carried", func() {
+		csrs, err := backend.List()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(csrs).To(HaveLen(1))
+
+		Expect(backend.Approve(&csrs[0])).To(Succeed())
+
+		updated, err := clientset.CertificatesV1beta1().CertificateSigningRequests().Get(context.TODO(), "csr-2", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updated.ResourceVersion).To(Equal("7"))
+		Expect(updated.UID).To(BeEquivalentTo("uid-2"))
+		Expect(updated.Status.Conditions).To(HaveLen(1))
+		Expect(updated.Status.Conditions[0].Type).To(Equal(certificatesv1beta1.CertificateApproved))
+	})
+
+	It("rejects approving a CSR that was never converted from a v1beta1 object", func() {
+		err := backend.Approve(&CSR{Name: "hand-built"})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,177 @@
+// Package dump provides a dependency-free must-gather replacement: it streams cluster resources
+// as individual JSON files into a size-capped, gzipped tar, one directory per resource kind, so
+// a caller can produce a diagnostic bundle without shelling out to the must-gather image or
+// holding an entire resource kind in memory at once.
+package dump
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxBytes caps the size of a dump bundle when Options.MaxBytes is left at zero.
+const DefaultMaxBytes = 50 * 1024 * 1024
+
+// tarBlockSize is the block size archive/tar pads every header and data section up to; it must be
+// included when estimating how many bytes a tar entry will actually add to the bundle.
+const tarBlockSize = 512
+
+// DefaultPodLogTailLines caps how many lines are kept of a crashing pod's log when
+// Options.PodLogTailLines is left at zero.
+const DefaultPodLogTailLines = 500
+
+// Options controls what DumpClusterState collects and how large the resulting bundle may grow.
+type Options struct {
+	// MaxBytes caps the total size of the tar entries written to the bundle, headers and block
+	// padding included, measured before gzip compression. Collection stops and returns an error
+	// once the cap would be exceeded, rather than silently truncating. Defaults to
+	// DefaultMaxBytes when zero.
+	MaxBytes int64
+	// NamespaceAllowlist, if non-empty, restricts namespaced collection (pods, logs, events)
+	// to these namespaces. Takes precedence over NamespaceDenylist.
+	NamespaceAllowlist []string
+	// NamespaceDenylist excludes these namespaces from namespaced collection.
+	NamespaceDenylist []string
+	// PodLogTailLines caps how many trailing lines are kept of a crashing pod's log. Defaults
+	// to DefaultPodLogTailLines when zero.
+	PodLogTailLines int64
+}
+
+// MaxBytesOrDefault returns o.MaxBytes, or DefaultMaxBytes when it is unset.
+func (o Options) MaxBytesOrDefault() int64 {
+	if o.MaxBytes <= 0 {
+		return DefaultMaxBytes
+	}
+	return o.MaxBytes
+}
+
+// PodLogTailLinesOrDefault returns o.PodLogTailLines, or DefaultPodLogTailLines when it is unset.
+func (o Options) PodLogTailLinesOrDefault() int64 {
+	if o.PodLogTailLines <= 0 {
+		return DefaultPodLogTailLines
+	}
+	return o.PodLogTailLines
+}
+
+// NamespaceAllowed reports whether ns should be included in namespaced collection, applying the
+// allow/deny list in Options.
+func (o Options) NamespaceAllowed(ns string) bool {
+	if len(o.NamespaceAllowlist) > 0 {
+		return contains(o.NamespaceAllowlist, ns)
+	}
+	return !contains(o.NamespaceDenylist, ns)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Writer streams resources into a gzipped tar, one file per item, enforcing a total size cap as
+// it goes. Items are written to the tar as soon as they're added rather than being buffered up
+// per resource kind, so the bundle's memory footprint stays bounded no matter how many items a
+// kind ends up holding across a large cluster.
+type Writer struct {
+	file     *os.File
+	gz       *gzip.Writer
+	tw       *tar.Writer
+	maxBytes int64
+	written  int64
+}
+
+// NewWriter creates the tar.gz at path, ready to accept resources via Resource.
+func NewWriter(path string, maxBytes int64) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating dump file %s", path)
+	}
+	gz := gzip.NewWriter(f)
+	return &Writer{file: f, gz: gz, tw: tar.NewWriter(gz), maxBytes: maxBytes}, nil
+}
+
+// Resource begins streaming a resource kind into the bundle. The caller adds items to the
+// returned ResourceWriter as it fetches them, instead of collecting them into a slice first.
+func (w *Writer) Resource(kind string) *ResourceWriter {
+	return &ResourceWriter{w: w, kind: kind}
+}
+
+// WriteResource streams items into the bundle under kind. It is a convenience wrapper around
+// Resource/Add for callers that already have every item of a kind in memory at once (e.g. the
+// result of a single List call).
+func (w *Writer) WriteResource(kind string, items []interface{}) error {
+	rw := w.Resource(kind)
+	for _, item := range items {
+		if err := rw.Add(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResourceWriter streams the items of a single resource kind into the bundle, one tar entry per
+// item, so items can be added as they're fetched rather than accumulated into a slice first.
+type ResourceWriter struct {
+	w     *Writer
+	kind  string
+	count int
+}
+
+// Add marshals item to JSON and writes it to the bundle as its own "<kind>/<NNNNNN>.json" tar
+// entry, checking the size cap before writing so collection stops as soon as the cap would be
+// exceeded instead of after the whole kind has already been buffered. The cap is checked against
+// the entry's full uncompressed tar footprint, header and block padding included, not just the JSON
+// payload, since a dump of many small resources (e.g. Events) is otherwise dominated by that
+// per-entry overhead rather than by the payload the cap is meant to bound.
+func (rw *ResourceWriter) Add(item interface{}) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return errors.Wrapf(err, "encoding %s resource", rw.kind)
+	}
+
+	entrySize := tarBlockSize + roundUpToBlock(int64(len(data)))
+	if rw.w.written+entrySize > rw.w.maxBytes {
+		return errors.Errorf("dump size cap of %d bytes exceeded while writing %s", rw.w.maxBytes, rw.kind)
+	}
+
+	header := &tar.Header{
+		Name: fmt.Sprintf("%s/%06d.json", rw.kind, rw.count),
+		Mode: 0644,
+		Size: int64(len(data)),
+	}
+	if err := rw.w.tw.WriteHeader(header); err != nil {
+		return errors.Wrapf(err, "writing tar header for %s", rw.kind)
+	}
+	if _, err := rw.w.tw.Write(data); err != nil {
+		return errors.Wrapf(err, "writing %s resource", rw.kind)
+	}
+
+	rw.w.written += entrySize
+	rw.count++
+	return nil
+}
+
+// roundUpToBlock rounds n up to the next multiple of tarBlockSize, matching the padding
+// archive/tar applies to each entry's data section.
+func roundUpToBlock(n int64) int64 {
+	return (n + tarBlockSize - 1) / tarBlockSize * tarBlockSize
+}
+
+// Close flushes and closes the tar, gzip, and underlying file, in that order.
+func (w *Writer) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	if err := w.gz.Close(); err != nil {
+		return err
+	}
+	return w.file.Close()
+}
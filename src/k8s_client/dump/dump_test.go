@@ -0,0 +1,151 @@
+package dump
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDump(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "dump Suite")
+}
+
+type tarEntry struct {
+	name string
+	data []byte
+}
+
+func readEntries(path string) []tarEntry {
+	f, err := os.Open(path)
+	Expect(err).ToNot(HaveOccurred())
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	Expect(err).ToNot(HaveOccurred())
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	var entries []tarEntry
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := io.ReadAll(tr)
+		Expect(err).ToNot(HaveOccurred())
+		entries = append(entries, tarEntry{name: header.Name, data: data})
+	}
+	return entries
+}
+
+var _ = Describe("Writer", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "dump.tar.gz")
+	})
+
+	It("streams each resource kind as one JSON file per item", func() {
+		w, err := NewWriter(path, DefaultMaxBytes)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(w.WriteResource("nodes", []interface{}{
+			map[string]string{"name": "node-1"},
+			map[string]string{"name": "node-2"},
+		})).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+
+		entries := readEntries(path)
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].name).To(Equal("nodes/000000.json"))
+		Expect(entries[1].name).To(Equal("nodes/000001.json"))
+
+		var decoded map[string]string
+		Expect(json.Unmarshal(entries[0].data, &decoded)).To(Succeed())
+		Expect(decoded["name"]).To(Equal("node-1"))
+	})
+
+	It("rejects items once the size cap would be exceeded", func() {
+		w, err := NewWriter(path, 1)
+		Expect(err).ToNot(HaveOccurred())
+		defer w.Close()
+
+		err = w.Resource("events").Add(map[string]string{"message": "too big for a 1 byte cap"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("counts per-entry tar overhead against the cap, not just the JSON payload", func() {
+		// A single small item easily fits the cap on payload size alone, but every tar entry
+		// also costs a 512-byte header plus padding; a cap sized just above the payload must
+		// still reject the entry once that overhead is taken into account.
+		item := map[string]string{"k": "v"}
+		data, err := json.Marshal(item)
+		Expect(err).ToNot(HaveOccurred())
+
+		w, err := NewWriter(path, int64(len(data))+1)
+		Expect(err).ToNot(HaveOccurred())
+		defer w.Close()
+
+		err = w.Resource("events").Add(item)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("stops a resource kind partway through once many small items exhaust the cap", func() {
+		// Regression guard: before per-entry overhead was counted, a cap sized for N small
+		// items' payloads alone let many more than N actually land in the bundle.
+		w, err := NewWriter(path, 3*tarBlockSize)
+		Expect(err).ToNot(HaveOccurred())
+		defer w.Close()
+
+		rw := w.Resource("events")
+		added := 0
+		for i := 0; i < 10; i++ {
+			if err := rw.Add(map[string]int{"i": i}); err != nil {
+				break
+			}
+			added++
+		}
+		Expect(added).To(BeNumerically("<", 10))
+	})
+})
+
+var _ = Describe("Options", func() {
+	It("defaults MaxBytes and PodLogTailLines when unset", func() {
+		o := Options{}
+		Expect(o.MaxBytesOrDefault()).To(Equal(int64(DefaultMaxBytes)))
+		Expect(o.PodLogTailLinesOrDefault()).To(Equal(int64(DefaultPodLogTailLines)))
+	})
+
+	It("honors an explicit MaxBytes and PodLogTailLines", func() {
+		o := Options{MaxBytes: 10, PodLogTailLines: 20}
+		Expect(o.MaxBytesOrDefault()).To(Equal(int64(10)))
+		Expect(o.PodLogTailLinesOrDefault()).To(Equal(int64(20)))
+	})
+
+	It("allows every namespace when neither list is set", func() {
+		o := Options{}
+		Expect(o.NamespaceAllowed("kube-system")).To(BeTrue())
+	})
+
+	It("restricts to the allowlist when set, ignoring the denylist", func() {
+		o := Options{NamespaceAllowlist: []string{"openshift-machine-api"}, NamespaceDenylist: []string{"openshift-machine-api"}}
+		Expect(o.NamespaceAllowed("openshift-machine-api")).To(BeTrue())
+		Expect(o.NamespaceAllowed("kube-system")).To(BeFalse())
+	})
+
+	It("excludes namespaces on the denylist when no allowlist is set", func() {
+		o := Options{NamespaceDenylist: []string{"kube-system"}}
+		Expect(o.NamespaceAllowed("kube-system")).To(BeFalse())
+		Expect(o.NamespaceAllowed("openshift-machine-api")).To(BeTrue())
+	})
+})
@@ -0,0 +1,191 @@
+package k8s_client
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/openshift/assisted-installer/src/k8s_client/dump"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// crashingPodPhases are the pod container states DumpClusterState pulls logs for, since a pod
+// stuck in one of these is almost always why an installation stalled.
+var crashingPodPhases = map[string]bool{
+	"CrashLoopBackOff": true,
+	"Error":            true,
+}
+
+// defaultDumpNamespaces are scanned for pods, crashing-pod logs, and events when
+// opts.NamespaceAllowlist is empty; they cover the components most likely to block install.
+var defaultDumpNamespaces = []string{"kube-system"}
+
+// DumpClusterState collects nodes, ClusterOperators, and pods/logs/events from the OpenShift and
+// kube-system namespaces into a gzipped tar under outDir, without shelling out to
+// `oc adm must-gather`. Each item is stored as its own JSON file in a directory per resource
+// kind; items of a kind are not combined into a single newline-delimited JSON file. It is meant to give the controller a fast diagnostic
+// bundle when installation stalls, in place of the several-minute must-gather image pull that
+// ops.Ops.GetMustGatherLogs incurs; the resulting bundle can be shipped through the same path as
+// ops.Ops.UploadInstallationLogs.
+func (c *k8sClient) DumpClusterState(ctx context.Context, outDir string, opts dump.Options) (err error) {
+	w, err := dump.NewWriter(filepath.Join(outDir, "cluster-state.tar.gz"), opts.MaxBytesOrDefault())
+	if err != nil {
+		return errors.Wrap(err, "creating dump writer")
+	}
+	defer func() {
+		if cerr := w.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "closing dump writer")
+		}
+	}()
+
+	if err := c.dumpNodes(ctx, w); err != nil {
+		return err
+	}
+	if err := c.dumpClusterOperators(ctx, w); err != nil {
+		return err
+	}
+	if err := c.dumpNamespacedResources(ctx, w, opts); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *k8sClient) dumpNodes(ctx context.Context, w *dump.Writer) error {
+	nodes, err := c.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "listing nodes for dump")
+	}
+
+	items := make([]interface{}, 0, len(nodes.Items))
+	for i := range nodes.Items {
+		items = append(items, &nodes.Items[i])
+	}
+	return w.WriteResource("nodes", items)
+}
+
+func (c *k8sClient) dumpClusterOperators(ctx context.Context, w *dump.Writer) error {
+	cos, err := c.configClient.ConfigV1().ClusterOperators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "listing cluster operators for dump")
+	}
+
+	items := make([]interface{}, 0, len(cos.Items))
+	for i := range cos.Items {
+		items = append(items, &cos.Items[i])
+	}
+	return w.WriteResource("clusteroperators", items)
+}
+
+// dumpNamespacedResources streams pods, events, and crashing-pod logs into w namespace by
+// namespace as they're fetched, rather than collecting every matched namespace's resources into
+// memory first, so the dump's memory footprint stays bounded on clusters with many namespaces or
+// pods.
+func (c *k8sClient) dumpNamespacedResources(ctx context.Context, w *dump.Writer, opts dump.Options) error {
+	namespaces, err := c.dumpNamespaceList(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	pods := w.Resource("pods")
+	events := w.Resource("events")
+	logs := w.Resource("crashing_pod_logs")
+
+	for _, ns := range namespaces {
+		if !opts.NamespaceAllowed(ns) {
+			continue
+		}
+
+		podList, err := c.client.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "listing pods in %s for dump", ns)
+		}
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			if err := pods.Add(pod); err != nil {
+				return err
+			}
+			if phase, crashing := crashingContainerPhase(pod); crashing {
+				if err := logs.Add(c.dumpPodLog(ctx, pod, phase, opts)); err != nil {
+					return err
+				}
+			}
+		}
+
+		eventList, err := c.client.CoreV1().Events(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "listing events in %s for dump", ns)
+		}
+		for i := range eventList.Items {
+			if err := events.Add(&eventList.Items[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// dumpNamespaceList enumerates openshift-* and kube-system namespaces, or the allowlist directly
+// when one is set, to avoid a full namespace list against large clusters when the caller has
+// already scoped the dump.
+func (c *k8sClient) dumpNamespaceList(ctx context.Context, opts dump.Options) ([]string, error) {
+	if len(opts.NamespaceAllowlist) > 0 {
+		return opts.NamespaceAllowlist, nil
+	}
+
+	nsList, err := c.client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing namespaces for dump")
+	}
+
+	namespaces := append([]string{}, defaultDumpNamespaces...)
+	for _, ns := range nsList.Items {
+		if len(ns.Name) > len("openshift-") && ns.Name[:len("openshift-")] == "openshift-" {
+			namespaces = append(namespaces, ns.Name)
+		}
+	}
+	return namespaces, nil
+}
+
+func crashingContainerPhase(pod *v1.Pod) (string, bool) {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && crashingPodPhases[cs.State.Waiting.Reason] {
+			return cs.State.Waiting.Reason, true
+		}
+		if cs.State.Terminated != nil && crashingPodPhases[cs.State.Terminated.Reason] {
+			return cs.State.Terminated.Reason, true
+		}
+	}
+	return "", false
+}
+
+type podLogDump struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Reason    string `json:"reason"`
+	Log       string `json:"log"`
+}
+
+func (c *k8sClient) dumpPodLog(ctx context.Context, pod *v1.Pod, reason string, opts dump.Options) podLogDump {
+	tail := opts.PodLogTailLinesOrDefault()
+	req := c.client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{TailLines: &tail})
+	stream, err := req.Stream(ctx)
+	if err != nil {
+		return podLogDump{Namespace: pod.Namespace, Pod: pod.Name, Reason: reason, Log: errors.Wrap(err, "fetching pod log").Error()}
+	}
+	defer stream.Close()
+
+	buf := make([]byte, 0, 4096)
+	chunk := make([]byte, 4096)
+	for {
+		n, err := stream.Read(chunk)
+		if n > 0 {
+			buf = append(buf, chunk[:n]...)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return podLogDump{Namespace: pod.Namespace, Pod: pod.Name, Reason: reason, Log: string(buf)}
+}
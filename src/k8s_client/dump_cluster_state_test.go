@@ -0,0 +1,105 @@
+package k8s_client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/assisted-installer/src/k8s_client/dump"
+)
+
+func crashLoopPod(namespace, name string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			},
+		},
+	}
+}
+
+var _ = Describe("crashingContainerPhase", func() {
+	It("reports the waiting reason for a crashing container", func() {
+		phase, crashing := crashingContainerPhase(crashLoopPod("kube-system", "pod-1"))
+		Expect(crashing).To(BeTrue())
+		Expect(phase).To(Equal("CrashLoopBackOff"))
+	})
+
+	It("reports false for a healthy container", func() {
+		pod := &v1.Pod{Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{
+			{State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+		}}}
+		_, crashing := crashingContainerPhase(pod)
+		Expect(crashing).To(BeFalse())
+	})
+})
+
+var _ = Describe("DumpClusterState", func() {
+	var (
+		client *k8sClient
+		outDir string
+	)
+
+	BeforeEach(func() {
+		outDir = GinkgoT().TempDir()
+		client = &k8sClient{
+			log:          logrus.New(),
+			client:       fake.NewSimpleClientset(),
+			configClient: configfake.NewSimpleClientset(),
+		}
+	})
+
+	It("dumps nodes, cluster operators, and namespaced resources into one tar.gz", func() {
+		client.client = fake.NewSimpleClientset(
+			&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+			crashLoopPod("kube-system", "crashing-pod"),
+			&v1.Event{ObjectMeta: metav1.ObjectMeta{Name: "event-1", Namespace: "kube-system"}},
+		)
+		client.configClient = configfake.NewSimpleClientset(&configv1.ClusterOperator{ObjectMeta: metav1.ObjectMeta{Name: "kube-apiserver"}})
+
+		Expect(client.DumpClusterState(context.Background(), outDir, dump.Options{})).To(Succeed())
+
+		info, err := os.Stat(filepath.Join(outDir, "cluster-state.tar.gz"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Size()).To(BeNumerically(">", 0))
+	})
+
+	It("scopes the namespace list to the allowlist when one is set", func() {
+		client.client = fake.NewSimpleClientset(
+			crashLoopPod("kube-system", "allowed-pod"),
+			crashLoopPod("openshift-monitoring", "denied-pod"),
+		)
+
+		namespaces, err := client.dumpNamespaceList(context.Background(), dump.Options{NamespaceAllowlist: []string{"kube-system"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(namespaces).To(ConsistOf("kube-system"))
+	})
+
+	It("discovers openshift-* namespaces and the kube-system default when no allowlist is set", func() {
+		client.client = fake.NewSimpleClientset(
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-monitoring"}},
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		)
+
+		namespaces, err := client.dumpNamespaceList(context.Background(), dump.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(namespaces).To(ConsistOf("kube-system", "openshift-monitoring"))
+	})
+
+	It("fails once the size cap is smaller than the collected resources", func() {
+		client.client = fake.NewSimpleClientset(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+
+		err := client.DumpClusterState(context.Background(), outDir, dump.Options{MaxBytes: 1})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -3,26 +3,28 @@ package k8s_client
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 
+	"github.com/openshift/assisted-installer/src/k8s_client/dump"
 	"github.com/openshift/assisted-installer/src/utils"
 	"k8s.io/apimachinery/pkg/labels"
 
-	"k8s.io/api/certificates/v1beta1"
-
 	"github.com/openshift/assisted-installer/src/ops"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/clientcmd"
 
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	machinev1beta1client "github.com/openshift/client-go/machine/clientset/versioned"
 	operatorv1 "github.com/openshift/client-go/operator/clientset/versioned"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	certificatesv1beta1client "k8s.io/client-go/kubernetes/typed/certificates/v1beta1"
+	"k8s.io/client-go/tools/cache"
 )
 
 //go:generate mockgen -source=k8s_client.go -package=k8s_client -destination=mock_k8s_client.go
@@ -32,21 +34,29 @@ type K8SClient interface {
 	UnPatchEtcd() error
 	ListNodes() (*v1.NodeList, error)
 	RunOCctlCommand(args []string, kubeconfigPath string, o ops.Ops) (string, error)
-	ApproveCsr(csr *v1beta1.CertificateSigningRequest) error
-	ListCsrs() (*v1beta1.CertificateSigningRequestList, error)
+	ApproveCsr(csr *CSR) error
+	ListCsrs() ([]CSR, error)
+	WatchAndApproveCSRs(ctx context.Context, predicate func(csr *CSR) bool) (<-chan CSRApprovalResult, error)
+	DumpClusterState(ctx context.Context, outDir string, opts dump.Options) error
 	GetConfigMap(namespace string, name string) (*v1.ConfigMap, error)
 	GetPodLogs(namespace string, podName string, sinceSeconds int64) (string, error)
 	GetPods(namespace string, labelMatch map[string]string) ([]v1.Pod, error)
+	PatchNodeTaints(nodeName string, addTaints, removeTaints []v1.Taint) error
+	RemoveStartupTaintsWhenReady(ctx context.Context, taintKeys []string, extraReady ...func(*v1.Node) bool) error
+	ListWorkerMachines() ([]machinev1beta1.Machine, error)
+	GetMachineForNode(nodeName string) (*machinev1beta1.Machine, error)
+	WorkersReady(minimum int) (bool, []string, error)
 }
 
 type K8SClientBuilder func(configPath string, logger *logrus.Logger) (K8SClient, error)
 
 type k8sClient struct {
-	log      *logrus.Logger
-	client   *kubernetes.Clientset
-	ocClient *operatorv1.Clientset
-	// CertificateSigningRequestInterface is interface
-	csrClient certificatesv1beta1client.CertificateSigningRequestInterface
+	log           *logrus.Logger
+	client        kubernetes.Interface
+	ocClient      *operatorv1.Clientset
+	csrBackend    csrBackend
+	machineClient machinev1beta1client.Interface
+	configClient  configv1client.Interface
 }
 
 func NewK8SClient(configPath string, logger *logrus.Logger) (K8SClient, error) {
@@ -62,9 +72,20 @@ func NewK8SClient(configPath string, logger *logrus.Logger) (K8SClient, error) {
 	if err != nil {
 		return &k8sClient{}, errors.Wrap(err, "creating a Kubernetes client")
 	}
-	csrClient := client.CertificatesV1beta1().CertificateSigningRequests()
+	machineClient, err := machinev1beta1client.NewForConfig(config)
+	if err != nil {
+		return &k8sClient{}, errors.Wrap(err, "creating a Machine API client")
+	}
+	csrBackend, err := newCSRBackend(client, logger)
+	if err != nil {
+		return &k8sClient{}, errors.Wrap(err, "negotiating certificates.k8s.io API version")
+	}
+	configClient, err := configv1client.NewForConfig(config)
+	if err != nil {
+		return &k8sClient{}, errors.Wrap(err, "creating a ClusterOperator client")
+	}
 
-	return &k8sClient{logger, client, ocClient, csrClient}, nil
+	return &k8sClient{logger, client, ocClient, csrBackend, machineClient, configClient}, nil
 }
 
 func (c *k8sClient) ListMasterNodes() (*v1.NodeList, error) {
@@ -115,8 +136,8 @@ func (c *k8sClient) RunOCctlCommand(args []string, kubeconfigPath string, o ops.
 	return outPut, nil
 }
 
-func (c k8sClient) ListCsrs() (*v1beta1.CertificateSigningRequestList, error) {
-	csrs, err := c.csrClient.List(context.TODO(), metav1.ListOptions{})
+func (c k8sClient) ListCsrs() ([]CSR, error) {
+	csrs, err := c.csrBackend.List()
 	if err != nil {
 		c.log.Errorf("Failed to get list of csrs. err : %e", err)
 		return nil, err
@@ -124,16 +145,9 @@ func (c k8sClient) ListCsrs() (*v1beta1.CertificateSigningRequestList, error) {
 	return csrs, nil
 }
 
-func (c k8sClient) ApproveCsr(csr *v1beta1.CertificateSigningRequest) error {
-
-	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1beta1.CertificateSigningRequestCondition{
-		Type:           certificatesv1beta1.CertificateApproved,
-		Reason:         "NodeCSRApprove",
-		Message:        "This CSR was approved by the assisted-installer-controller",
-		LastUpdateTime: metav1.Now(),
-	})
-	if _, err := c.csrClient.UpdateApproval(context.TODO(), csr, metav1.UpdateOptions{}); err != nil {
-		c.log.Errorf("Failed to approve csr %v, err %e", csr, err)
+func (c k8sClient) ApproveCsr(csr *CSR) error {
+	if err := c.csrBackend.Approve(csr); err != nil {
+		c.log.Errorf("Failed to approve csr %v, err %e", csr.Name, err)
 		return err
 	}
 	return nil
@@ -162,6 +176,211 @@ func (c *k8sClient) GetPods(namespace string, labelMatch map[string]string) ([]v
 	return pod.Items, nil
 }
 
+// PatchNodeTaints reconciles the taints on a node, adding addTaints and removing any taint
+// whose key matches one in removeTaints. It is idempotent: a taint that is already present or
+// already absent is left untouched, and nodes that need no change are not patched at all.
+func (c *k8sClient) PatchNodeTaints(nodeName string, addTaints, removeTaints []v1.Taint) error {
+	node, err := c.client.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to get node %s", nodeName)
+	}
+
+	removeKeys := make(map[string]bool)
+	for _, t := range removeTaints {
+		removeKeys[t.Key] = true
+	}
+
+	newTaints := make([]v1.Taint, 0, len(node.Spec.Taints)+len(addTaints))
+	for _, t := range node.Spec.Taints {
+		if !removeKeys[t.Key] {
+			newTaints = append(newTaints, t)
+		}
+	}
+	for _, t := range addTaints {
+		if !taintsContain(newTaints, t) {
+			newTaints = append(newTaints, t)
+		}
+	}
+
+	if taintsEqual(node.Spec.Taints, newTaints) {
+		c.log.Infof("Node %s taints already up to date, skipping patch", nodeName)
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"taints": newTaints,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal taints patch")
+	}
+
+	_, err = c.client.CoreV1().Nodes().Patch(context.TODO(), nodeName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to patch taints on node %s", nodeName)
+	}
+	return nil
+}
+
+// RemoveStartupTaintsWhenReady scans all nodes and, for every node whose Kubelet has reported
+// Ready=True and which satisfies every extraReady check, strips the given startup taint keys. It
+// is meant to replace out-of-band scripts that hold workloads off freshly-added workers until
+// CNI/MCO settle on them. Callers that need to gate on more than kubelet readiness (e.g. a
+// CNI-specific or MCO-specific node condition) can pass one or more extraReady checks; a node is
+// only considered ready once Kubelet reports Ready=True and all of them return true.
+func (c *k8sClient) RemoveStartupTaintsWhenReady(ctx context.Context, taintKeys []string, extraReady ...func(*v1.Node) bool) error {
+	nodes, err := c.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "Failed to list nodes")
+	}
+
+	removeTaints := make([]v1.Taint, 0, len(taintKeys))
+	for _, key := range taintKeys {
+		removeTaints = append(removeTaints, v1.Taint{Key: key})
+	}
+
+	for _, node := range nodes.Items {
+		if !nodeReady(&node, extraReady...) {
+			continue
+		}
+		if err := c.PatchNodeTaints(node.Name, nil, removeTaints); err != nil {
+			return errors.Wrapf(err, "Failed to remove startup taints from node %s", node.Name)
+		}
+	}
+	return nil
+}
+
+// nodeReady reports whether node's Kubelet has reported Ready=True and, if any extraReady checks
+// are given, whether all of them also return true for node.
+func nodeReady(node *v1.Node, extraReady ...func(*v1.Node) bool) bool {
+	kubeletReady := false
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1.NodeReady {
+			kubeletReady = cond.Status == v1.ConditionTrue
+			break
+		}
+	}
+	if !kubeletReady {
+		return false
+	}
+
+	for _, check := range extraReady {
+		if !check(node) {
+			return false
+		}
+	}
+	return true
+}
+
+func taintsContain(taints []v1.Taint, taint v1.Taint) bool {
+	for _, t := range taints {
+		if t.Key == taint.Key && t.Value == taint.Value && t.Effect == taint.Effect {
+			return true
+		}
+	}
+	return false
+}
+
+func taintsEqual(a, b []v1.Taint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, t := range a {
+		if !taintsContain(b, t) {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	machineAPINamespace   = "openshift-machine-api"
+	machineRoleLabel      = "machine.openshift.io/cluster-api-machine-role"
+	machineAnnotationNode = "machine.openshift.io/machine"
+)
+
+// ListWorkerMachines returns all Machine objects in the machine-api namespace labelled with
+// the worker role.
+func (c *k8sClient) ListWorkerMachines() ([]machinev1beta1.Machine, error) {
+	machines, err := c.machineClient.MachineV1beta1().Machines(machineAPINamespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=worker", machineRoleLabel),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to list worker machines")
+	}
+	return machines.Items, nil
+}
+
+// GetMachineForNode finds the Machine backing a given Node, correlating via the
+// machine.openshift.io/machine annotation set on the node by the machine-api operator.
+func (c *k8sClient) GetMachineForNode(nodeName string) (*machinev1beta1.Machine, error) {
+	node, err := c.client.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get node %s", nodeName)
+	}
+
+	ref, ok := node.Annotations[machineAnnotationNode]
+	if !ok {
+		return nil, errors.Errorf("node %s has no %s annotation", nodeName, machineAnnotationNode)
+	}
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(ref)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse machine annotation %q on node %s", ref, nodeName)
+	}
+
+	machine, err := c.machineClient.MachineV1beta1().Machines(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get machine %s/%s for node %s", namespace, name, nodeName)
+	}
+	return machine, nil
+}
+
+// WorkersReady counts worker Machines in the Running phase and cross-checks that the Node each
+// one references (via status.nodeRef) reports Ready=True. It returns whether at least minimum
+// such machines are ready, along with one entry per machine that is not, giving its name, phase,
+// and raw providerStatus, so the caller can log the stragglers rather than just a node count.
+func (c *k8sClient) WorkersReady(minimum int) (bool, []string, error) {
+	machines, err := c.ListWorkerMachines()
+	if err != nil {
+		return false, nil, err
+	}
+
+	var notReady []string
+	readyCount := 0
+	for _, machine := range machines {
+		if machine.Status.Phase == nil || *machine.Status.Phase != "Running" {
+			notReady = append(notReady, c.describeNotReadyMachine(&machine))
+			continue
+		}
+		if machine.Status.NodeRef == nil {
+			notReady = append(notReady, c.describeNotReadyMachine(&machine))
+			continue
+		}
+		node, err := c.client.CoreV1().Nodes().Get(context.TODO(), machine.Status.NodeRef.Name, metav1.GetOptions{})
+		if err != nil || !nodeReady(node) {
+			notReady = append(notReady, c.describeNotReadyMachine(&machine))
+			continue
+		}
+		readyCount++
+	}
+
+	return readyCount >= minimum, notReady, nil
+}
+
+func (c *k8sClient) describeNotReadyMachine(machine *machinev1beta1.Machine) string {
+	phase := "unknown"
+	if machine.Status.Phase != nil {
+		phase = *machine.Status.Phase
+	}
+	providerStatus := ""
+	if machine.Status.ProviderStatus != nil {
+		providerStatus = string(machine.Status.ProviderStatus.Raw)
+	}
+	return fmt.Sprintf("%s (phase=%s, providerStatus=%s)", machine.Name, phase, providerStatus)
+}
+
 func (c *k8sClient) GetPodLogs(namespace string, podName string, sinceSeconds int64) (string, error) {
 	podLogOpts := v1.PodLogOptions{}
 	if sinceSeconds > 0 {
@@ -0,0 +1,128 @@
+package k8s_client
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinefake "github.com/openshift/client-go/machine/clientset/versioned/fake"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func runningPhase() *string {
+	phase := "Running"
+	return &phase
+}
+
+func newWorkerMachine(name, nodeName string, phase *string) *machinev1beta1.Machine {
+	machine := &machinev1beta1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: machineAPINamespace,
+			Labels:    map[string]string{machineRoleLabel: "worker"},
+		},
+		Status: machinev1beta1.MachineStatus{Phase: phase},
+	}
+	if nodeName != "" {
+		machine.Status.NodeRef = &v1.ObjectReference{Name: nodeName}
+	}
+	return machine
+}
+
+var _ = Describe("GetMachineForNode", func() {
+	var client *k8sClient
+
+	BeforeEach(func() {
+		node := &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "worker-1",
+				Annotations: map[string]string{machineAnnotationNode: machineAPINamespace + "/worker-1-machine"},
+			},
+		}
+		client = &k8sClient{
+			log:           logrus.New(),
+			client:        fake.NewSimpleClientset(node),
+			machineClient: machinefake.NewSimpleClientset(newWorkerMachine("worker-1-machine", "worker-1", runningPhase())),
+		}
+	})
+
+	It("finds the Machine referenced by the node's machine annotation", func() {
+		machine, err := client.GetMachineForNode("worker-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(machine.Name).To(Equal("worker-1-machine"))
+	})
+
+	It("errors when the node has no machine annotation", func() {
+		client.client = fake.NewSimpleClientset(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "no-annotation"}})
+		_, err := client.GetMachineForNode("no-annotation")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("WorkersReady", func() {
+	It("reports ready once enough worker machines are Running with a Ready node", func() {
+		readyNode := &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+			Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}},
+		}
+		client := &k8sClient{
+			log:    logrus.New(),
+			client: fake.NewSimpleClientset(readyNode),
+			machineClient: machinefake.NewSimpleClientset(
+				newWorkerMachine("worker-1-machine", "worker-1", runningPhase()),
+			),
+		}
+
+		ready, notReady, err := client.WorkersReady(1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ready).To(BeTrue())
+		Expect(notReady).To(BeEmpty())
+	})
+
+	It("counts a machine whose node isn't Ready as not ready", func() {
+		notReadyNode := &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+			Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionFalse}}},
+		}
+		client := &k8sClient{
+			log:    logrus.New(),
+			client: fake.NewSimpleClientset(notReadyNode),
+			machineClient: machinefake.NewSimpleClientset(
+				newWorkerMachine("worker-1-machine", "worker-1", runningPhase()),
+			),
+		}
+
+		ready, notReady, err := client.WorkersReady(1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ready).To(BeFalse())
+		Expect(notReady).To(HaveLen(1))
+	})
+
+	It("counts a machine that hasn't reached the Running phase as not ready", func() {
+		client := &k8sClient{
+			log:           logrus.New(),
+			client:        fake.NewSimpleClientset(),
+			machineClient: machinefake.NewSimpleClientset(newWorkerMachine("worker-1-machine", "", nil)),
+		}
+
+		ready, notReady, err := client.WorkersReady(1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ready).To(BeFalse())
+		Expect(notReady).To(HaveLen(1))
+	})
+
+	It("falls short of minimum when fewer machines are ready than requested", func() {
+		client := &k8sClient{
+			log:           logrus.New(),
+			client:        fake.NewSimpleClientset(),
+			machineClient: machinefake.NewSimpleClientset(),
+		}
+
+		ready, notReady, err := client.WorkersReady(1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ready).To(BeFalse())
+		Expect(notReady).To(BeEmpty())
+	})
+})
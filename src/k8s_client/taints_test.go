@@ -0,0 +1,127 @@
+package k8s_client
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTaintedNode(name string, taints []v1.Taint) *v1.Node {
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: v1.NodeSpec{Taints: taints}}
+}
+
+func nodeTaints(clientset *fake.Clientset, name string) []v1.Taint {
+	node, err := clientset.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+	Expect(err).ToNot(HaveOccurred())
+	return node.Spec.Taints
+}
+
+var _ = Describe("PatchNodeTaints", func() {
+	var (
+		clientset *fake.Clientset
+		client    *k8sClient
+	)
+
+	addTaint := v1.Taint{Key: "node.ocp.io/startup", Effect: v1.TaintEffectNoSchedule}
+
+	BeforeEach(func() {
+		clientset = fake.NewSimpleClientset(newTaintedNode("node-1", nil))
+		client = &k8sClient{log: logrus.New(), client: clientset}
+	})
+
+	It("adds a taint that is not yet present", func() {
+		Expect(client.PatchNodeTaints("node-1", []v1.Taint{addTaint}, nil)).To(Succeed())
+		Expect(nodeTaints(clientset, "node-1")).To(ConsistOf(addTaint))
+	})
+
+	It("removes a taint whose key matches, leaving others untouched", func() {
+		other := v1.Taint{Key: "other", Value: "x", Effect: v1.TaintEffectNoSchedule}
+		clientset = fake.NewSimpleClientset(newTaintedNode("node-1", []v1.Taint{addTaint, other}))
+		client = &k8sClient{log: logrus.New(), client: clientset}
+
+		Expect(client.PatchNodeTaints("node-1", nil, []v1.Taint{{Key: addTaint.Key}})).To(Succeed())
+		Expect(nodeTaints(clientset, "node-1")).To(ConsistOf(other))
+	})
+
+	It("adds and removes taints in the same call", func() {
+		stale := v1.Taint{Key: "stale", Effect: v1.TaintEffectNoSchedule}
+		clientset = fake.NewSimpleClientset(newTaintedNode("node-1", []v1.Taint{stale}))
+		client = &k8sClient{log: logrus.New(), client: clientset}
+
+		Expect(client.PatchNodeTaints("node-1", []v1.Taint{addTaint}, []v1.Taint{{Key: stale.Key}})).To(Succeed())
+		Expect(nodeTaints(clientset, "node-1")).To(ConsistOf(addTaint))
+	})
+
+	It("is idempotent: re-applying the same add/remove patch is a no-op", func() {
+		Expect(client.PatchNodeTaints("node-1", []v1.Taint{addTaint}, nil)).To(Succeed())
+		Expect(client.PatchNodeTaints("node-1", []v1.Taint{addTaint}, nil)).To(Succeed())
+		Expect(nodeTaints(clientset, "node-1")).To(ConsistOf(addTaint))
+	})
+})
+
+var _ = Describe("RemoveStartupTaintsWhenReady", func() {
+	var (
+		clientset *fake.Clientset
+		client    *k8sClient
+	)
+
+	startupTaint := v1.Taint{Key: "node.ocp.io/startup", Effect: v1.TaintEffectNoSchedule}
+
+	readyNode := func(name string, ready bool, taints []v1.Taint) *v1.Node {
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		node := newTaintedNode(name, taints)
+		node.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: status}}
+		return node
+	}
+
+	BeforeEach(func() {
+		clientset = fake.NewSimpleClientset(
+			readyNode("ready-node", true, []v1.Taint{startupTaint}),
+			readyNode("not-ready-node", false, []v1.Taint{startupTaint}),
+		)
+		client = &k8sClient{log: logrus.New(), client: clientset}
+	})
+
+	It("strips the startup taint from Ready nodes but leaves it on nodes that aren't Ready", func() {
+		Expect(client.RemoveStartupTaintsWhenReady(context.TODO(), []string{startupTaint.Key})).To(Succeed())
+
+		Expect(nodeTaints(clientset, "ready-node")).To(BeEmpty())
+		Expect(nodeTaints(clientset, "not-ready-node")).To(ConsistOf(startupTaint))
+	})
+
+	It("leaves the taint on a Kubelet-ready node that fails an extraReady check", func() {
+		extraReady := func(node *v1.Node) bool { return false }
+
+		Expect(client.RemoveStartupTaintsWhenReady(context.TODO(), []string{startupTaint.Key}, extraReady)).To(Succeed())
+
+		Expect(nodeTaints(clientset, "ready-node")).To(ConsistOf(startupTaint))
+		Expect(nodeTaints(clientset, "not-ready-node")).To(ConsistOf(startupTaint))
+	})
+
+	It("strips the taint from a node that is Kubelet-ready and passes all extraReady checks", func() {
+		extraReady := func(node *v1.Node) bool { return true }
+
+		Expect(client.RemoveStartupTaintsWhenReady(context.TODO(), []string{startupTaint.Key}, extraReady)).To(Succeed())
+
+		Expect(nodeTaints(clientset, "ready-node")).To(BeEmpty())
+		Expect(nodeTaints(clientset, "not-ready-node")).To(ConsistOf(startupTaint))
+	})
+
+	It("leaves the taint in place when only one of several extraReady checks fails", func() {
+		passingCheck := func(node *v1.Node) bool { return true }
+		failingCheck := func(node *v1.Node) bool { return false }
+
+		Expect(client.RemoveStartupTaintsWhenReady(context.TODO(), []string{startupTaint.Key}, passingCheck, failingCheck)).To(Succeed())
+
+		Expect(nodeTaints(clientset, "ready-node")).To(ConsistOf(startupTaint))
+		Expect(nodeTaints(clientset, "not-ready-node")).To(ConsistOf(startupTaint))
+	})
+})
@@ -0,0 +1,71 @@
+package k8s_client
+
+import (
+	"context"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CSRApprovalResult reports the outcome of approving a single CSR observed by
+// WatchAndApproveCSRs.
+type CSRApprovalResult struct {
+	Name string
+	Err  error
+}
+
+// registerCSRHandlers wires an informer's add/update callbacks to onChange, converting the raw
+// object via convert and skipping CSRs that already carry an Approved or Denied condition.
+func registerCSRHandlers(informer cache.SharedIndexInformer, onChange func(*CSR), convert func(interface{}) (*CSR, bool)) {
+	handle := func(obj interface{}) {
+		csr, ok := convert(obj)
+		if !ok || csrDecided(csr) {
+			return
+		}
+		onChange(csr)
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    handle,
+		UpdateFunc: func(_, newObj interface{}) { handle(newObj) },
+	})
+}
+
+func csrDecided(csr *CSR) bool {
+	for _, c := range csr.Conditions {
+		if c.Type == "Approved" || c.Type == "Denied" {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchAndApproveCSRs replaces a periodic List-and-approve loop with an event-driven one: it
+// builds a SharedInformerFactory over CertificateSigningRequests, approves every pending CSR
+// accepted by predicate, and reports the outcome of each approval on the returned channel. It
+// blocks in the background until ctx is cancelled; the returned channel is closed once the watch
+// loop exits. Transient watch disconnects are handled by the informer's own Reflector, which
+// already retries List/Watch with exponential backoff internally, so informer.Run only returns
+// once ctx is cancelled and this function does not need a retry loop of its own.
+func (c *k8sClient) WatchAndApproveCSRs(ctx context.Context, predicate func(csr *CSR) bool) (<-chan CSRApprovalResult, error) {
+	results := make(chan CSRApprovalResult)
+
+	onChange := func(csr *CSR) {
+		if !predicate(csr) {
+			return
+		}
+		err := c.ApproveCsr(csr)
+		select {
+		case results <- CSRApprovalResult{Name: csr.Name, Err: err}:
+		case <-ctx.Done():
+		}
+	}
+
+	go func() {
+		defer close(results)
+		factory := informers.NewSharedInformerFactory(c.client, 0)
+		informer := c.csrBackend.informer(factory, onChange)
+		informer.Run(ctx.Done())
+	}()
+
+	return results, nil
+}
@@ -0,0 +1,170 @@
+package k8s_client
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func pendingV1CSR(name string) *certificatesv1.CertificateSigningRequest {
+	return &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Username:   "system:node:" + name,
+			SignerName: "kubernetes.io/kube-apiserver-client-kubelet",
+		},
+	}
+}
+
+var _ = Describe("csrDecided", func() {
+	It("reports false for a CSR with no conditions", func() {
+		Expect(csrDecided(&CSR{})).To(BeFalse())
+	})
+
+	It("reports true for a CSR already Approved", func() {
+		Expect(csrDecided(&CSR{Conditions: []CSRCondition{{Type: "Approved"}}})).To(BeTrue())
+	})
+
+	It("reports true for a CSR already Denied", func() {
+		Expect(csrDecided(&CSR{Conditions: []CSRCondition{{Type: "Denied"}}})).To(BeTrue())
+	})
+})
+
+var _ = Describe("registerCSRHandlers", func() {
+	It("forwards add events for objects the converter accepts, converted to the version-neutral CSR", func() {
+		clientset := fake.NewSimpleClientset(pendingV1CSR("csr-1"))
+		factory := informers.NewSharedInformerFactory(clientset, 0)
+		informer := factory.Certificates().V1().CertificateSigningRequests().Informer()
+
+		var received []*CSR
+		registerCSRHandlers(informer, func(csr *CSR) { received = append(received, csr) }, func(obj interface{}) (*CSR, bool) {
+			csr, ok := obj.(*certificatesv1.CertificateSigningRequest)
+			if !ok {
+				return nil, false
+			}
+			converted := fromV1(csr)
+			return &converted, true
+		})
+
+		stop := make(chan struct{})
+		defer close(stop)
+		factory.Start(stop)
+		Expect(cache.WaitForCacheSync(stop, informer.HasSynced)).To(BeTrue())
+
+		Eventually(func() []*CSR { return received }).Should(HaveLen(1))
+		Expect(received[0].Name).To(Equal("csr-1"))
+	})
+
+	It("skips objects the converter rejects", func() {
+		clientset := fake.NewSimpleClientset(pendingV1CSR("csr-1"))
+		factory := informers.NewSharedInformerFactory(clientset, 0)
+		informer := factory.Certificates().V1().CertificateSigningRequests().Informer()
+
+		called := false
+		registerCSRHandlers(informer, func(*CSR) { called = true }, func(interface{}) (*CSR, bool) { return nil, false })
+
+		stop := make(chan struct{})
+		defer close(stop)
+		factory.Start(stop)
+		Expect(cache.WaitForCacheSync(stop, informer.HasSynced)).To(BeTrue())
+
+		Consistently(func() bool { return called }, 300*time.Millisecond).Should(BeFalse())
+	})
+
+	It("skips CSRs that already carry a decided condition", func() {
+		clientset := fake.NewSimpleClientset()
+		factory := informers.NewSharedInformerFactory(clientset, 0)
+		informer := factory.Certificates().V1().CertificateSigningRequests().Informer()
+
+		var received []*CSR
+		registerCSRHandlers(informer, func(csr *CSR) { received = append(received, csr) }, func(obj interface{}) (*CSR, bool) {
+			csr, ok := obj.(*certificatesv1.CertificateSigningRequest)
+			if !ok {
+				return nil, false
+			}
+			converted := fromV1(csr)
+			return &converted, true
+		})
+
+		stop := make(chan struct{})
+		defer close(stop)
+		factory.Start(stop)
+		Expect(cache.WaitForCacheSync(stop, informer.HasSynced)).To(BeTrue())
+
+		decided := pendingV1CSR("already-decided")
+		decided.Status.Conditions = []certificatesv1.CertificateSigningRequestCondition{{Type: certificatesv1.CertificateApproved}}
+		_, err := clientset.CertificatesV1().CertificateSigningRequests().Create(context.TODO(), decided, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Consistently(func() []*CSR { return received }, 300*time.Millisecond).Should(BeEmpty())
+	})
+})
+
+var _ = Describe("WatchAndApproveCSRs", func() {
+	var (
+		clientset *fake.Clientset
+		client    *k8sClient
+	)
+
+	BeforeEach(func() {
+		clientset = fake.NewSimpleClientset()
+		client = &k8sClient{
+			log:        logrus.New(),
+			client:     clientset,
+			csrBackend: &csrBackendV1{client: clientset.CertificatesV1().CertificateSigningRequests(), log: logrus.New()},
+		}
+	})
+
+	It("approves and reports only CSRs accepted by the predicate", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		results, err := client.WatchAndApproveCSRs(ctx, func(csr *CSR) bool { return csr.Name == "wanted" })
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = clientset.CertificatesV1().CertificateSigningRequests().Create(ctx, pendingV1CSR("ignored"), metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = clientset.CertificatesV1().CertificateSigningRequests().Create(ctx, pendingV1CSR("wanted"), metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		var result CSRApprovalResult
+		Eventually(results, 2*time.Second).Should(Receive(&result))
+		Expect(result.Name).To(Equal("wanted"))
+		Expect(result.Err).ToNot(HaveOccurred())
+
+		Consistently(results, 200*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("never delivers a CSR that already carries a decided condition", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		decided := pendingV1CSR("already-decided")
+		decided.Status.Conditions = []certificatesv1.CertificateSigningRequestCondition{{Type: certificatesv1.CertificateApproved}}
+		_, err := clientset.CertificatesV1().CertificateSigningRequests().Create(ctx, decided, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		results, err := client.WatchAndApproveCSRs(ctx, func(*CSR) bool { return true })
+		Expect(err).ToNot(HaveOccurred())
+
+		Consistently(results, 300*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("closes the results channel once ctx is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		results, err := client.WatchAndApproveCSRs(ctx, func(*CSR) bool { return true })
+		Expect(err).ToNot(HaveOccurred())
+
+		cancel()
+
+		Eventually(results, time.Second).Should(BeClosed())
+	})
+})